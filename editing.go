@@ -0,0 +1,341 @@
+package faketerm
+
+/*
+ * editing.go
+ * Interactive line editing for FakeTerm
+ * By J. Stuart McMurray
+ * Created 20260726
+ * Last Modified 20260726
+ */
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInterrupted is returned by ReadLine and ReadPassword, when FakeTerm is
+// in editing mode, if Ctrl-C is read.
+var ErrInterrupted = errors.New("faketerm: interrupted")
+
+// NewEditing is like New, but returns a FakeTerm with interactive line
+// editing enabled; see SetEditing.
+func NewEditing(r io.Reader, w io.Writer) *FakeTerm {
+	f := New(r, w)
+	f.editing = true
+	return f
+}
+
+// SetEditing turns FakeTerm's interactive line-editing mode on or off.  In
+// editing mode, ReadLine and ReadPassword parse the usual terminal control
+// characters (Ctrl-A/E/B/F move, Backspace/Ctrl-H and Ctrl-D delete, Ctrl-K/U/W
+// kill, Ctrl-L clears the screen, Ctrl-D returns io.EOF on an empty line,
+// Ctrl-C returns ErrInterrupted) as well as the CSI arrow sequences
+// (ESC [ A/B/C/D) for cursor movement and history navigation, rather than
+// simply scanning to the next newline.  Edits are echoed back through the
+// underlying io.Writer, repainting whatever prompt is set with SetPrompt.
+//
+// SetEditing may be called between calls to ReadLine or ReadPassword: both
+// modes read from the underlying io.Reader through the same buffer, so
+// input already buffered by one mode is still there for the other.
+func (f *FakeTerm) SetEditing(on bool) {
+	f.rL.Lock()
+	defer f.rL.Unlock()
+	f.editing = on
+}
+
+// SetHistorySize sets the number of lines of input history a FakeTerm in
+// editing mode keeps for use with the up/down arrow keys.  The default,
+// used if SetHistorySize is never called, is 100.  SetHistorySize
+// discards any history collected so far.  A negative n is treated as 0,
+// disabling history.
+func (f *FakeTerm) SetHistorySize(n int) {
+	f.rL.Lock()
+	defer f.rL.Unlock()
+	if 0 > n {
+		n = 0
+	}
+	f.histSize = n
+	f.hist = newHistory(n)
+}
+
+// ensureHistory lazily creates f.hist the first time it's needed.  It must
+// be called with f.rL held.
+func (f *FakeTerm) ensureHistory() *history {
+	if nil == f.hist {
+		f.hist = newHistory(f.histSize)
+	}
+	return f.hist
+}
+
+// readLineEditing is the editing-mode implementation of ReadLine and
+// ReadPassword.  It must be called with f.rL held.  When echo is false,
+// as for ReadPassword, input is still parsed and edited normally, but
+// nothing is echoed back.
+func (f *FakeTerm) readLineEditing(echo bool) (string, error) {
+	e := &lineEditor{
+		f:    f,
+		r:    f.ensureReader(),
+		h:    f.ensureHistory(),
+		echo: echo,
+		hPos: -1,
+	}
+	return e.run()
+}
+
+// lineEditor holds the state of a single in-progress ReadLine or
+// ReadPassword call in editing mode.
+type lineEditor struct {
+	f    *FakeTerm
+	r    *bufio.Reader
+	h    *history
+	echo bool
+
+	buf []rune /* Current line, as edited so far. */
+	pos int    /* Cursor position in buf, in runes. */
+
+	hPos  int    /* Index into h, or -1 if not browsing history. */
+	saved string /* buf as typed, before browsing into history. */
+}
+
+// run reads and edits bytes from e.r until a line is completed or an error
+// occurs.
+func (e *lineEditor) run() (string, error) {
+	if err := e.redraw(); nil != err {
+		return "", err
+	}
+	for {
+		b, err := e.r.ReadByte()
+		if nil != err {
+			return "", err
+		}
+		done, line, err := e.handle(b)
+		if ErrPasteIndicator == err {
+			return line, err
+		}
+		if nil != err {
+			return "", err
+		}
+		if done {
+			return line, nil
+		}
+		if err := e.redraw(); nil != err {
+			return "", err
+		}
+	}
+}
+
+// handle applies the effect of a single byte read from e.r.  If done is
+// true, line is the completed line and e.run should return it.
+func (e *lineEditor) handle(b byte) (done bool, line string, err error) {
+	switch b {
+	case 0x03: /* Ctrl-C */
+		return false, "", ErrInterrupted
+	case 0x04: /* Ctrl-D */
+		if 0 == len(e.buf) {
+			return false, "", io.EOF
+		}
+		e.deleteForward()
+	case 0x01: /* Ctrl-A: start of line */
+		e.pos = 0
+	case 0x05: /* Ctrl-E: end of line */
+		e.pos = len(e.buf)
+	case 0x02: /* Ctrl-B: back one char */
+		if e.pos > 0 {
+			e.pos--
+		}
+	case 0x06: /* Ctrl-F: forward one char */
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case 0x08, 0x7f: /* Backspace */
+		e.backspace()
+	case 0x0b: /* Ctrl-K: kill to end of line */
+		e.buf = e.buf[:e.pos]
+	case 0x15: /* Ctrl-U: kill to start of line */
+		e.buf = append([]rune{}, e.buf[e.pos:]...)
+		e.pos = 0
+	case 0x17: /* Ctrl-W: kill previous word */
+		e.deleteWord()
+	case 0x0c: /* Ctrl-L: clear screen */
+		return false, "", e.f.writeLocked([]byte("\x1b[H\x1b[2J"))
+	case '\r', '\n':
+		line = string(e.buf)
+		if err := e.f.writeLocked([]byte("\r\n")); nil != err {
+			return false, "", err
+		}
+		/* Don't remember passwords: only echoed (i.e. ReadLine, not
+		 * ReadPassword) lines go into history. */
+		if e.echo && "" != line {
+			e.h.push(line)
+		}
+		return true, line, nil
+	case 0x1b: /* ESC: maybe a CSI sequence */
+		return e.escape()
+	default:
+		if b >= 0x20 && b < 0x7f {
+			e.insert(rune(b))
+		}
+		/* Anything else is ignored. */
+	}
+	return false, "", err
+}
+
+// insert inserts r into e.buf at e.pos and advances the cursor past it.
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf[:e.pos], append([]rune{r}, e.buf[e.pos:]...)...)
+	e.pos++
+}
+
+// backspace deletes the rune before the cursor, if any.
+func (e *lineEditor) backspace() {
+	if e.pos > 0 {
+		e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+		e.pos--
+	}
+}
+
+// deleteForward deletes the rune under the cursor, if any.
+func (e *lineEditor) deleteForward() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+// deleteWord deletes the word before the cursor, as well as any trailing
+// spaces between it and the cursor.
+func (e *lineEditor) deleteWord() {
+	i := e.pos
+	for i > 0 && ' ' == e.buf[i-1] {
+		i--
+	}
+	for i > 0 && ' ' != e.buf[i-1] {
+		i--
+	}
+	e.buf = append(e.buf[:i], e.buf[e.pos:]...)
+	e.pos = i
+}
+
+// escape reads the rest of a CSI sequence started by an ESC already read
+// from e.r and applies its effect.  If the sequence is the start of a
+// bracketed paste, escape reads the whole pasted block and returns it as
+// line, done, paired with ErrPasteIndicator, per SetBracketedPasteMode.
+// Sequences other than the arrow keys and a bracketed paste are read as
+// far as recognized and otherwise ignored.
+func (e *lineEditor) escape() (done bool, line string, err error) {
+	b1, err := e.r.ReadByte()
+	if nil != err {
+		return false, "", err
+	}
+	if '[' != b1 {
+		return false, "", nil
+	}
+	b2, err := e.r.ReadByte()
+	if nil != err {
+		return false, "", err
+	}
+	switch {
+	case 'A' == b2: /* Up: older history */
+		e.historyPrev()
+	case 'B' == b2: /* Down: newer history */
+		e.historyNext()
+	case 'C' == b2: /* Right */
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case 'D' == b2: /* Left */
+		if e.pos > 0 {
+			e.pos--
+		}
+	case b2 >= '0' && b2 <= '9' && e.f.bracketedPaste:
+		return e.maybePaste(b2)
+	}
+	return false, "", nil
+}
+
+// maybePaste reads the rest of a numeric CSI sequence, having already read
+// its first digit as first, and if it's a bracketed-paste start marker
+// (ESC [ 200 ~), reads and returns the pasted text.  Other numeric CSI
+// sequences are read as far as the terminating '~' and ignored.
+func (e *lineEditor) maybePaste(first byte) (done bool, line string, err error) {
+	digits := []byte{first}
+	for {
+		b, err := e.r.ReadByte()
+		if nil != err {
+			return false, "", err
+		}
+		if '~' == b {
+			break
+		}
+		digits = append(digits, b)
+	}
+	if "200" != string(digits) {
+		return false, "", nil
+	}
+	text, err := e.f.readPastedText()
+	if nil != err {
+		return false, "", err
+	}
+	return true, text, ErrPasteIndicator
+}
+
+// historyPrev scrolls back to the previous (older) history entry, saving
+// the in-progress line the first time it's called.
+func (e *lineEditor) historyPrev() {
+	if e.hPos+1 >= e.h.len() {
+		return
+	}
+	if -1 == e.hPos {
+		e.saved = string(e.buf)
+	}
+	e.hPos++
+	line, _ := e.h.at(e.hPos)
+	e.buf = []rune(line)
+	e.pos = len(e.buf)
+}
+
+// historyNext scrolls forward to the next (newer) history entry, or
+// restores the in-progress line saved by historyPrev once history is
+// exhausted.
+func (e *lineEditor) historyNext() {
+	switch {
+	case e.hPos > 0:
+		e.hPos--
+		line, _ := e.h.at(e.hPos)
+		e.buf = []rune(line)
+		e.pos = len(e.buf)
+	case 0 == e.hPos:
+		e.hPos = -1
+		e.buf = []rune(e.saved)
+		e.pos = len(e.buf)
+	}
+}
+
+// redraw repaints the prompt and current line, positioning the cursor at
+// e.pos.  When e.echo is false, as for ReadPassword, the line's contents
+// are never written, only the prompt.
+func (e *lineEditor) redraw() error {
+	var b strings.Builder
+	b.WriteByte('\r')
+	b.WriteString(e.f.getPrompt())
+	if e.echo {
+		b.WriteString(string(e.buf))
+	}
+	b.WriteString("\x1b[K")
+	if e.echo {
+		if back := len(e.buf) - e.pos; back > 0 {
+			fmt.Fprintf(&b, "\x1b[%dD", back)
+		}
+	}
+	return e.f.writeLocked([]byte(b.String()))
+}
+
+// writeLocked writes p through f.Write, so that editing-mode echoes and
+// redraws participate in the same width-wrapping and column tracking as
+// any other output; see SetSize.
+func (f *FakeTerm) writeLocked(p []byte) error {
+	_, err := f.Write(p)
+	return err
+}
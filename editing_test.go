@@ -0,0 +1,145 @@
+package faketerm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// linesFn returns a Script program which reads n lines in editing mode,
+// writing "LINE<i>:<line>\n" after each, or "ERR:<err>\n" and returning
+// early if ReadLine fails.
+func linesFn(n int) func(f *FakeTerm) {
+	return func(f *FakeTerm) {
+		f.SetEditing(true)
+		for i := 0; i < n; i++ {
+			line, err := f.ReadLine()
+			if nil != err {
+				f.Write([]byte(fmt.Sprintf("ERR:%v\n", err)))
+				return
+			}
+			f.Write([]byte(fmt.Sprintf("LINE%d:%s\n", i, line)))
+		}
+	}
+}
+
+func TestEditingBasicLine(t *testing.T) {
+	sc := NewScript(linesFn(1))
+	defer sc.Close()
+	sc.Send("hello\n")
+	sc.Expect("LINE0:hello")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingCursorMovementAndInsert(t *testing.T) {
+	sc := NewScript(linesFn(1))
+	defer sc.Close()
+	/* Type "hllo", back up 3 with Ctrl-B, insert "e": "hello". */
+	sc.Send("hllo\x02\x02\x02e\n")
+	sc.Expect("LINE0:hello")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingKillToStartOfLine(t *testing.T) {
+	sc := NewScript(linesFn(1))
+	defer sc.Close()
+	/* Ctrl-U kills from the cursor (end of line) to the start. */
+	sc.Send("hello world\x15bye\n")
+	sc.Expect("LINE0:bye")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingKillPreviousWord(t *testing.T) {
+	sc := NewScript(linesFn(1))
+	defer sc.Close()
+	/* Ctrl-W kills the previous word. */
+	sc.Send("foo bar\x17baz\n")
+	sc.Expect("LINE0:foo baz")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingHistoryUpArrow(t *testing.T) {
+	sc := NewScript(linesFn(4))
+	defer sc.Close()
+	sc.Send("first\n")
+	sc.Expect("LINE0:first")
+	sc.Send("second\n")
+	sc.Expect("LINE1:second")
+	sc.Send("third\n")
+	sc.Expect("LINE2:third")
+	/* Three ups from a fresh line walks back to the oldest entry. */
+	sc.Send("\x1b[A\x1b[A\x1b[A\n")
+	sc.Expect("LINE3:first")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingHistoryDownRestoresInProgressLine(t *testing.T) {
+	sc := NewScript(linesFn(2))
+	defer sc.Close()
+	sc.Send("alpha\n")
+	sc.Expect("LINE0:alpha")
+	/* Type "be", scroll up into history and back down, then finish
+	 * typing: the in-progress "be" should survive the round trip. */
+	sc.Send("be\x1b[A\x1b[Bta\n")
+	sc.Expect("LINE1:beta")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingCtrlDOnEmptyLineReturnsEOF(t *testing.T) {
+	sc := NewScript(linesFn(1))
+	defer sc.Close()
+	sc.Send("\x04")
+	sc.Expect(fmt.Sprintf("ERR:%v", io.EOF))
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingCtrlCReturnsErrInterrupted(t *testing.T) {
+	sc := NewScript(linesFn(1))
+	defer sc.Close()
+	sc.Send("\x03")
+	sc.Expect(fmt.Sprintf("ERR:%v", ErrInterrupted))
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestEditingPasswordNotAddedToHistory(t *testing.T) {
+	sc := NewScript(func(f *FakeTerm) {
+		f.SetEditing(true)
+		if _, err := f.ReadPassword("pw: "); nil != err {
+			f.Write([]byte(fmt.Sprintf("ERR:%v\n", err)))
+			return
+		}
+		line, err := f.ReadLine()
+		if nil != err {
+			f.Write([]byte(fmt.Sprintf("ERR:%v\n", err)))
+			return
+		}
+		f.Write([]byte("LINE:" + line + "\n"))
+	})
+	defer sc.Close()
+	sc.Send("hunter2\n")
+	sc.Send("\x1b[A\n") /* Up-arrow: history should be empty, so a no-op. */
+	sc.Expect("LINE:")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+	if transcript := sc.Transcript(); strings.Contains(transcript, "hunter2") {
+		t.Fatalf("password leaked into transcript via history: %q", transcript)
+	}
+}
@@ -7,13 +7,12 @@ package faketerm
  * Fake terminal which acts like a real one
  * By J. Stuart McMurray
  * Created 20220327
- * Last Modified 20220327
+ * Last Modified 20260726
  */
 
 import (
 	"bufio"
 	"io"
-	"strings"
 	"sync"
 )
 
@@ -32,51 +31,131 @@ type Term interface {
 
 // FakeTerm is a Term with an underlying io.ReadWriter.  Its methods are
 // analogs of term.Terminals with differences noted.
+//
+// By default, ReadLine and ReadPassword read whole lines from the
+// underlying io.Reader, splitting on \n, \r\n or a lone \r.  Calling SetEditing (or
+// constructing with NewEditing) switches FakeTerm into an interactive
+// line-editing mode; see SetEditing for details.  Either mode honors
+// SetBracketedPasteMode.
 type FakeTerm struct {
 	w  io.Writer
 	wL sync.Mutex
-	s  *bufio.Scanner
-	rL sync.Mutex
+
+	rdr io.Reader
+	r   *bufio.Reader
+	rL  sync.Mutex
+
+	editing        bool
+	histSize       int
+	hist           *history
+	bracketedPaste bool
+
+	prompt  string
+	promptL sync.Mutex
+
+	width, height int
+	onResize      []func(width, height int)
+	sizeL         sync.Mutex
+	col           int
+	escState      int
 }
 
-// New returns a new FakeTerm, ready for use
+// New returns a new FakeTerm, ready for use.  ReadLine and ReadPassword
+// simply read whole lines from r; for interactive line editing, see
+// NewEditing.
 func New(r io.Reader, w io.Writer) *FakeTerm {
 	return &FakeTerm{
-		w: w,
-		s: bufio.NewScanner(r),
+		w:        w,
+		rdr:      r,
+		histSize: defaultHistorySize,
 	}
 }
 
+// ensureReader lazily wraps f.rdr in a bufio.Reader the first time a
+// byte-level read is needed.  It must be called with f.rL held.
+func (f *FakeTerm) ensureReader() *bufio.Reader {
+	if nil == f.r {
+		f.r = bufio.NewReader(f.rdr)
+	}
+	return f.r
+}
+
+// ReadLine reads and returns a line of input.  In editing mode, see
+// SetEditing for the semantics; otherwise it reads up to the next \n,
+// \r\n or lone \r.  If SetBracketedPasteMode(true) has been called and the line was
+// the product of a bracketed paste, ReadLine returns it paired with
+// ErrPasteIndicator rather than nil.
 func (f *FakeTerm) ReadLine() (line string, err error) {
 	f.rL.Lock()
 	defer f.rL.Unlock()
-	/* Wait for a line to be available. */
-	if !f.s.Scan() {
-		err := f.s.Err()
-		if nil == err {
-			return "", io.EOF
+	if f.editing {
+		return f.readLineEditing(true)
+	}
+	return f.readLineScan()
+}
+
+// readLineScan is the non-editing implementation of ReadLine.  It must be
+// called with f.rL held.  As with bufio.Scanner, a final line with no
+// trailing line terminator is still returned, with a nil error; the error from
+// r is only returned once there's no more buffered data to return.
+func (f *FakeTerm) readLineScan() (line string, err error) {
+	r := f.ensureReader()
+	var buf []byte
+	for {
+		b, err := r.ReadByte()
+		if nil != err {
+			if len(buf) > 0 {
+				return string(buf), nil
+			}
+			return "", err
+		}
+		if f.bracketedPaste && 0x1b == b && consumeIfPeek(r, pasteStartSeq) {
+			text, err := f.readPastedText()
+			if nil != err {
+				return "", err
+			}
+			return text, ErrPasteIndicator
+		}
+		if '\r' == b || '\n' == b {
+			if '\r' == b {
+				if nb, err := r.Peek(1); nil == err && 1 == len(nb) && '\n' == nb[0] {
+					r.Discard(1)
+				}
+			}
+			return string(buf), nil
 		}
-		return "", f.s.Err()
+		buf = append(buf, b)
 	}
-	return strings.TrimRight(f.s.Text(), "\r\n"), nil
 }
 
-// ReadPassword is a thin wrapper around f.ReadLine.  The prompt is ignored.
+// ReadPassword is like ReadLine, but using prompt in place of whatever was
+// set with SetPrompt.  Outside of editing mode the prompt is unused, since
+// nothing is echoed back anyways.
 func (f *FakeTerm) ReadPassword(prompt string) (line string, err error) {
-	return f.ReadLine()
+	f.rL.Lock()
+	defer f.rL.Unlock()
+	if !f.editing {
+		return f.readLineScan()
+	}
+	old := f.getPrompt()
+	f.SetPrompt(prompt)
+	defer f.SetPrompt(old)
+	return f.readLineEditing(false)
 }
 
-// SetBracketedPasteMode is a no-op
-func (f *FakeTerm) SetBracketedPasteMode(on bool) {}
-
-// SetPrompt is a no-op.
-func (f *FakeTerm) SetPrompt(prompt string) {}
-
-// SetSize is a no-op.
-func (f *FakeTerm) SetSize(width, height int) error { return nil }
+// SetPrompt sets the prompt written before each line read in editing mode;
+// it has no effect otherwise.  It's safe to call SetPrompt while a call to
+// ReadLine or ReadPassword is in progress, e.g. from another goroutine, in
+// which case the new prompt is used on the next redraw.
+func (f *FakeTerm) SetPrompt(prompt string) {
+	f.promptL.Lock()
+	defer f.promptL.Unlock()
+	f.prompt = prompt
+}
 
-func (f *FakeTerm) Write(buf []byte) (n int, err error) {
-	f.wL.Lock()
-	defer f.wL.Unlock()
-	return f.w.Write(buf)
+// getPrompt returns the prompt currently set with SetPrompt.
+func (f *FakeTerm) getPrompt() string {
+	f.promptL.Lock()
+	defer f.promptL.Unlock()
+	return f.prompt
 }
@@ -0,0 +1,21 @@
+package faketerm
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadLineUnterminatedFinalLine(t *testing.T) {
+	f := New(strings.NewReader("hello"), io.Discard)
+	line, err := f.ReadLine()
+	if nil != err {
+		t.Fatalf("ReadLine: unexpected error %v", err)
+	}
+	if "hello" != line {
+		t.Fatalf("ReadLine: got %q, want %q", line, "hello")
+	}
+	if _, err := f.ReadLine(); io.EOF != err {
+		t.Fatalf("second ReadLine: got err %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,56 @@
+package faketerm
+
+/*
+ * history.go
+ * Bounded history buffer for FakeTerm's line editor
+ * By J. Stuart McMurray
+ * Created 20260726
+ * Last Modified 20260726
+ */
+
+// defaultHistorySize is the number of lines of history a FakeTerm keeps
+// when none is set with SetHistorySize.
+const defaultHistorySize = 100
+
+// history is a bounded FIFO of previously-entered lines, oldest first.
+// Once full, pushing a new line evicts the oldest.  A nil *history behaves
+// as an empty, zero-capacity history.
+type history struct {
+	lines []string
+	max   int
+}
+
+// newHistory returns a history which keeps at most max lines.  A max of 0
+// disables history; push becomes a no-op.
+func newHistory(max int) *history {
+	return &history{max: max}
+}
+
+// push appends line to h, evicting the oldest line if h is already at
+// capacity.  A non-positive max disables history entirely.
+func (h *history) push(line string) {
+	if nil == h || 0 >= h.max {
+		return
+	}
+	h.lines = append(h.lines, line)
+	if len(h.lines) > h.max {
+		h.lines = h.lines[len(h.lines)-h.max:]
+	}
+}
+
+// at returns the i'th most-recently-pushed line (0 is the most recent) and
+// true, or "", false if there is no such line.
+func (h *history) at(i int) (line string, ok bool) {
+	if nil == h || 0 > i || i >= len(h.lines) {
+		return "", false
+	}
+	return h.lines[len(h.lines)-1-i], true
+}
+
+// len returns the number of lines currently in h.
+func (h *history) len() int {
+	if nil == h {
+		return 0
+	}
+	return len(h.lines)
+}
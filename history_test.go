@@ -0,0 +1,45 @@
+package faketerm
+
+import (
+	"io"
+	"testing"
+)
+
+func TestHistoryPushNegativeMaxDoesNotPanic(t *testing.T) {
+	h := newHistory(-1)
+	h.push("a")
+	if 0 != h.len() {
+		t.Fatalf("len() = %d, want 0", h.len())
+	}
+}
+
+func TestSetHistorySizeClampsNegative(t *testing.T) {
+	f := New(nil, io.Discard)
+	f.SetHistorySize(-5)
+	if 0 != f.histSize {
+		t.Fatalf("histSize = %d, want 0", f.histSize)
+	}
+	f.ensureHistory().push("a")
+	if 0 != f.hist.len() {
+		t.Fatalf("hist.len() = %d, want 0", f.hist.len())
+	}
+}
+
+func TestHistoryPushAndAt(t *testing.T) {
+	h := newHistory(2)
+	h.push("a")
+	h.push("b")
+	h.push("c") /* evicts "a" */
+	if 2 != h.len() {
+		t.Fatalf("len() = %d, want 2", h.len())
+	}
+	if line, ok := h.at(0); !ok || "c" != line {
+		t.Fatalf("at(0) = %q, %v, want %q, true", line, ok, "c")
+	}
+	if line, ok := h.at(1); !ok || "b" != line {
+		t.Fatalf("at(1) = %q, %v, want %q, true", line, ok, "b")
+	}
+	if _, ok := h.at(2); ok {
+		t.Fatalf("at(2) ok, want false")
+	}
+}
@@ -0,0 +1,70 @@
+package faketerm
+
+/*
+ * paste.go
+ * Bracketed paste handling for FakeTerm
+ * By J. Stuart McMurray
+ * Created 20260726
+ * Last Modified 20260726
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+)
+
+// ErrPasteIndicator is returned by ReadLine and ReadPassword in place of a
+// nil error when the returned line is the product of a bracketed paste
+// rather than having been typed, mirroring term.Terminal.ReadLine.  It's
+// only returned once SetBracketedPasteMode(true) has been called.
+var ErrPasteIndicator = errors.New("faketerm: paste indicator")
+
+// pasteStartSeq and pasteEndSeq are the CSI sequences, less the leading
+// ESC, which bracket a paste under the xterm bracketed-paste-mode
+// convention.
+var (
+	pasteStartSeq = []byte("[200~")
+	pasteEndSeq   = []byte("[201~")
+)
+
+// SetBracketedPasteMode turns bracketed-paste recognition on or off.  When
+// on, ReadLine and ReadPassword recognize the ESC [ 200 ~ ... ESC [ 201 ~
+// framing xterm and friends use to mark a paste and return the whole
+// pasted block, embedded newlines and all, as a single line paired with
+// ErrPasteIndicator, rather than splitting it on newlines.
+func (f *FakeTerm) SetBracketedPasteMode(on bool) {
+	f.rL.Lock()
+	defer f.rL.Unlock()
+	f.bracketedPaste = on
+}
+
+// consumeIfPeek reports whether the next len(seq) bytes available from r
+// equal seq, consuming them if so and leaving r untouched otherwise.
+func consumeIfPeek(r *bufio.Reader, seq []byte) bool {
+	b, err := r.Peek(len(seq))
+	if nil != err || !bytes.Equal(b, seq) {
+		return false
+	}
+	r.Discard(len(seq))
+	return true
+}
+
+// readPastedText reads and returns the contents of a bracketed paste, up
+// to (but not including) the ESC [ 201 ~ terminator, which is consumed.
+// The ESC [ 200 ~ start marker must already have been consumed from f's
+// underlying reader.
+func (f *FakeTerm) readPastedText() (string, error) {
+	r := f.ensureReader()
+	var buf []byte
+	for {
+		b, err := r.ReadByte()
+		if nil != err {
+			return "", err
+		}
+		if 0x1b == b && consumeIfPeek(r, pasteEndSeq) {
+			return string(buf), nil
+		}
+		buf = append(buf, b)
+	}
+}
@@ -0,0 +1,48 @@
+package faketerm
+
+/*
+ * raw.go
+ * Term implementation backed by a real tty, via golang.org/x/term
+ * By J. Stuart McMurray
+ * Created 20260726
+ * Last Modified 20260726
+ */
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// NewRaw puts the file descriptor fd into raw mode with term.MakeRaw and
+// returns a *FakeTerm, in editing mode, which reads and writes fd.  The
+// returned restore func must be called, typically with defer, to put fd
+// back into its original mode with term.Restore; it's nil whenever a
+// non-nil error is returned.
+//
+// NewRaw means a single import of faketerm covers both the fake
+// (io.Reader/io.Writer-backed) and real (fd-backed, raw-mode) cases
+// behind the Term interface; pair it with IsTerminal and GetSize to avoid
+// also importing golang.org/x/term directly.
+func NewRaw(fd int) (ft *FakeTerm, restore func() error, err error) {
+	old, err := term.MakeRaw(fd)
+	if nil != err {
+		return nil, nil, err
+	}
+	f := os.NewFile(uintptr(fd), "")
+	return NewEditing(f, f), func() error {
+		return term.Restore(fd, old)
+	}, nil
+}
+
+// IsTerminal re-exports term.IsTerminal, so that deciding whether NewRaw
+// makes sense for fd doesn't require also importing golang.org/x/term.
+func IsTerminal(fd int) bool {
+	return term.IsTerminal(fd)
+}
+
+// GetSize re-exports term.GetSize, so that feeding a real fd's dimensions
+// to SetSize doesn't require also importing golang.org/x/term.
+func GetSize(fd int) (width, height int, err error) {
+	return term.GetSize(fd)
+}
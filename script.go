@@ -0,0 +1,216 @@
+package faketerm
+
+/*
+ * script.go
+ * Expect-style driver for testing programs that use a Term
+ * By J. Stuart McMurray
+ * Created 20260726
+ * Last Modified 20260726
+ */
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultScriptTimeout is how long Expect and ExpectRegexp wait for a
+// match before failing, unless changed with SetTimeout.
+const defaultScriptTimeout = 2 * time.Second
+
+// scriptPollInterval is how often Expect and ExpectRegexp check for a
+// match while waiting.
+const scriptPollInterval = 5 * time.Millisecond
+
+// Script drives a *FakeTerm from a sequence of expectations, in the style
+// of Tcl's expect, for testing a program which reads and writes a Term.
+// A zero-value Script is not usable; use NewScript.
+//
+// Script's Send, Expect, ExpectRegexp and SetTimeout methods return
+// *Script, so that steps may be chained, e.g.:
+//
+//	sc := faketerm.NewScript(func(f *faketerm.FakeTerm) { login(f) })
+//	sc.Expect("login: ").
+//		Send("alice\n").
+//		ExpectRegexp(`password:\s*$`).
+//		Send("hunter2\n")
+//	if err := sc.Err(); nil != err {
+//		t.Fatal(err)
+//	}
+//
+// Once a step fails, later steps become no-ops and the failure's error is
+// available from Err.
+type Script struct {
+	term *FakeTerm
+
+	inW  *io.PipeWriter
+	outR *io.PipeReader
+	outW *io.PipeWriter
+
+	timeout time.Duration
+
+	mu         sync.Mutex
+	transcript bytes.Buffer
+	pos        int
+	err        error
+}
+
+// NewScript starts fn, which should drive a *FakeTerm the way a program
+// under test would drive a term.Terminal, in its own goroutine, and
+// returns a Script for feeding it input and checking its output.  Under
+// the hood, fn is handed a FakeTerm backed by a pair of io.Pipes; Send
+// writes to the input pipe and Expect/ExpectRegexp read from the output
+// pipe.
+func NewScript(fn func(f *FakeTerm)) *Script {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	s := &Script{
+		term:    New(inR, outW),
+		inW:     inW,
+		outR:    outR,
+		outW:    outW,
+		timeout: defaultScriptTimeout,
+	}
+	go s.drain()
+	go fn(s.term)
+	return s
+}
+
+// Term returns the *FakeTerm passed to the fn given to NewScript.
+func (s *Script) Term() *FakeTerm { return s.term }
+
+// SetTimeout sets how long subsequent calls to Expect and ExpectRegexp
+// wait for a match before failing.  The default is 2 seconds.
+func (s *Script) SetTimeout(d time.Duration) *Script {
+	s.timeout = d
+	return s
+}
+
+// Send writes data to the program under test, as if typed.  It does not
+// append a newline; include one, e.g. "alice\n", to submit a line.
+func (s *Script) Send(data string) *Script {
+	if nil != s.err {
+		return s
+	}
+	if _, err := s.inW.Write([]byte(data)); nil != err {
+		s.fail(fmt.Errorf("faketerm: sending %q: %w", data, err))
+	}
+	return s
+}
+
+// Expect waits for want to appear in the program's output, consuming
+// output through the end of the match.  It fails if want doesn't appear
+// within the current timeout.
+func (s *Script) Expect(want string) *Script {
+	return s.expect(
+		func(tail string) (int, bool) {
+			i := strings.Index(tail, want)
+			if i < 0 {
+				return 0, false
+			}
+			return i + len(want), true
+		},
+		fmt.Sprintf("%q", want),
+	)
+}
+
+// ExpectRegexp is like Expect, but waits for a match of pattern instead of
+// a literal string.
+func (s *Script) ExpectRegexp(pattern string) *Script {
+	re, err := regexp.Compile(pattern)
+	if nil != err {
+		s.fail(fmt.Errorf("faketerm: compiling %q: %w", pattern, err))
+		return s
+	}
+	return s.expect(
+		func(tail string) (int, bool) {
+			loc := re.FindStringIndex(tail)
+			if nil == loc {
+				return 0, false
+			}
+			return loc[1], true
+		},
+		fmt.Sprintf("regexp %q", pattern),
+	)
+}
+
+// expect polls the transcript collected since the last match for match,
+// which should report how much of tail to consume and whether it
+// matched, until it succeeds or the timeout set with SetTimeout elapses.
+func (s *Script) expect(match func(tail string) (int, bool), desc string) *Script {
+	if nil != s.err {
+		return s
+	}
+	deadline := time.Now().Add(s.timeout)
+	for {
+		s.mu.Lock()
+		tail := string(s.transcript.Bytes()[s.pos:])
+		if n, ok := match(tail); ok {
+			s.pos += n
+			s.mu.Unlock()
+			return s
+		}
+		got := s.transcript.String()
+		s.mu.Unlock()
+		if time.Now().After(deadline) {
+			s.fail(fmt.Errorf(
+				"faketerm: timed out waiting for %s; output so far:\n%s",
+				desc, got,
+			))
+			return s
+		}
+		time.Sleep(scriptPollInterval)
+	}
+}
+
+// fail records err as s's error, if s doesn't already have one.
+func (s *Script) fail(err error) {
+	if nil == s.err {
+		s.err = err
+	}
+}
+
+// Err returns the error from the first failed step, or nil if every step
+// so far has succeeded.
+func (s *Script) Err() error {
+	return s.err
+}
+
+// Transcript returns everything written by the program under test so
+// far, matched or not.
+func (s *Script) Transcript() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.transcript.String()
+}
+
+// Close closes the pipes backing s's FakeTerm, which typically causes the
+// program under test's next read or write to fail with io.ErrClosedPipe
+// or similar.  Close should be called once a Script is no longer needed.
+func (s *Script) Close() error {
+	err1 := s.inW.Close()
+	err2 := s.outW.Close()
+	return errors.Join(err1, err2)
+}
+
+// drain copies everything written by the program under test into s's
+// transcript, until its output pipe is closed.
+func (s *Script) drain() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.outR.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			s.transcript.Write(buf[:n])
+			s.mu.Unlock()
+		}
+		if nil != err {
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package faketerm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScriptSendExpect(t *testing.T) {
+	sc := NewScript(func(f *FakeTerm) {
+		line, err := f.ReadLine()
+		if nil != err {
+			return
+		}
+		f.Write([]byte("echo: " + line + "\n"))
+	})
+	defer sc.Close()
+	sc.Send("hello\n").Expect("echo: hello")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestScriptExpectRegexp(t *testing.T) {
+	sc := NewScript(func(f *FakeTerm) {
+		f.Write([]byte("password: "))
+	})
+	defer sc.Close()
+	sc.ExpectRegexp(`password:\s*$`)
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestScriptExpectTimeoutReportsTranscript(t *testing.T) {
+	sc := NewScript(func(f *FakeTerm) {
+		f.Write([]byte("unrelated output"))
+	})
+	defer sc.Close()
+	sc.SetTimeout(20 * time.Millisecond)
+	sc.Expect("never going to appear")
+	err := sc.Err()
+	if nil == err {
+		t.Fatal("Expect succeeded, want timeout error")
+	}
+	if !strings.Contains(err.Error(), "unrelated output") {
+		t.Fatalf("error %q doesn't include the transcript so far", err)
+	}
+}
+
+func TestScriptFailedStepShortCircuitsLaterSteps(t *testing.T) {
+	sc := NewScript(func(f *FakeTerm) {
+		f.Write([]byte("hi"))
+	})
+	defer sc.Close()
+	sc.SetTimeout(20 * time.Millisecond)
+	sc.Expect("nope")
+	first := sc.Err()
+	sc.Expect("hi") /* Would succeed, but a prior step already failed. */
+	if sc.Err() != first {
+		t.Fatalf("Err() changed after a step run past the first failure")
+	}
+}
+
+func TestScriptBracketedPaste(t *testing.T) {
+	sc := NewScript(func(f *FakeTerm) {
+		f.SetBracketedPasteMode(true)
+		line, err := f.ReadLine()
+		if ErrPasteIndicator == err {
+			f.Write([]byte("PASTE:" + line + "\n"))
+			return
+		}
+		f.Write([]byte("LINE:" + line + "\n"))
+	})
+	defer sc.Close()
+	sc.Send("\x1b[200~line1\nline2\x1b[201~")
+	sc.Expect("PASTE:line1\nline2")
+	if err := sc.Err(); nil != err {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,109 @@
+package faketerm
+
+/*
+ * size.go
+ * Width/height tracking and resize notification for FakeTerm
+ * By J. Stuart McMurray
+ * Created 20260726
+ * Last Modified 20260726
+ */
+
+// SetSize sets FakeTerm's declared width and height.  A non-zero width
+// causes Write to insert hard \r\n line breaks so that output wraps at
+// that width, mirroring how term.Terminal uses width for cursor
+// advancement.  If either dimension changed, any callbacks registered
+// with OnResize are invoked with the new width and height.
+func (f *FakeTerm) SetSize(width, height int) error {
+	f.sizeL.Lock()
+	changed := width != f.width || height != f.height
+	f.width, f.height = width, height
+	hooks := f.onResize
+	f.sizeL.Unlock()
+	if changed {
+		for _, fn := range hooks {
+			fn(width, height)
+		}
+	}
+	return nil
+}
+
+// getWidth returns the width most recently set with SetSize.
+func (f *FakeTerm) getWidth() int {
+	f.sizeL.Lock()
+	defer f.sizeL.Unlock()
+	return f.width
+}
+
+// OnResize registers fn to be called with FakeTerm's new width and height
+// whenever SetSize changes them, acting as a SIGWINCH-style hook.  This
+// lets a program written against Term react to resizes driven by an
+// out-of-band channel, e.g. an SSH server feeding window-change requests
+// into SetSize.  OnResize may be called more than once to register
+// multiple callbacks; they're called in the order registered.
+func (f *FakeTerm) OnResize(fn func(width, height int)) {
+	f.sizeL.Lock()
+	defer f.sizeL.Unlock()
+	f.onResize = append(f.onResize, fn)
+}
+
+// Escape-sequence tracking states for f.escState, used by Write to keep
+// CSI sequences (and other two-byte escapes) from being counted towards,
+// or split across, the tracked column.
+const (
+	escNone  = iota /* Not in an escape sequence. */
+	escStart        /* Just saw ESC; next byte decides what kind. */
+	escCSI          /* In the body of a CSI (ESC [ ...) sequence. */
+)
+
+// Write writes buf to the underlying io.Writer.  If SetSize has been
+// called with a non-zero width, Write inserts a hard \r\n break whenever
+// the current line would otherwise exceed that width, tracking column
+// position across calls; escape sequences, including multi-byte CSI
+// sequences such as those editing.go's redraw emits, are passed through
+// without counting towards the column.
+func (f *FakeTerm) Write(buf []byte) (n int, err error) {
+	f.wL.Lock()
+	defer f.wL.Unlock()
+	width := f.getWidth()
+	if 0 == width {
+		return f.w.Write(buf)
+	}
+	out := make([]byte, 0, len(buf))
+	for _, b := range buf {
+		switch f.escState {
+		case escStart:
+			out = append(out, b)
+			if '[' == b {
+				f.escState = escCSI
+			} else {
+				f.escState = escNone
+			}
+			continue
+		case escCSI:
+			out = append(out, b)
+			if b >= 0x40 && b <= 0x7e {
+				f.escState = escNone
+			}
+			continue
+		}
+		switch {
+		case 0x1b == b:
+			f.escState = escStart
+			out = append(out, b)
+		case '\r' == b || '\n' == b:
+			f.col = 0
+			out = append(out, b)
+		default:
+			if f.col >= width {
+				out = append(out, '\r', '\n')
+				f.col = 0
+			}
+			out = append(out, b)
+			f.col++
+		}
+	}
+	if _, err := f.w.Write(out); nil != err {
+		return 0, err
+	}
+	return len(buf), nil
+}
@@ -0,0 +1,46 @@
+package faketerm
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestWriteLockedHonorsWidth verifies that editing-mode echoes, which go
+// through writeLocked rather than the exported Write, still participate
+// in SetSize's width wrapping instead of bypassing it.
+func TestWriteLockedHonorsWidth(t *testing.T) {
+	var out bytes.Buffer
+	f := New(bytes.NewReader(nil), &out)
+	if err := f.SetSize(5, 0); nil != err {
+		t.Fatalf("SetSize: %v", err)
+	}
+	if err := f.writeLocked([]byte("abcdefgh")); nil != err {
+		t.Fatalf("writeLocked: %v", err)
+	}
+	got := out.String()
+	want := "abcde\r\nfgh"
+	if got != want {
+		t.Fatalf("writeLocked didn't wrap at width; got %q, want %q", got, want)
+	}
+}
+
+// TestWriteDoesNotSplitCSISequences reproduces a redraw payload like the
+// one editing.go's redraw emits, and checks that Write's column tracking
+// doesn't mistake the CSI introducer '[' for the sequence's final byte,
+// which would otherwise count the rest of the sequence towards the
+// column and potentially split it with an inserted \r\n.
+func TestWriteDoesNotSplitCSISequences(t *testing.T) {
+	var out bytes.Buffer
+	f := New(bytes.NewReader(nil), &out)
+	if err := f.SetSize(10, 0); nil != err {
+		t.Fatalf("SetSize: %v", err)
+	}
+	if _, err := f.Write([]byte("\rprompt> hello world\x1b[K\x1b[11D")); nil != err {
+		t.Fatalf("Write: %v", err)
+	}
+	got := out.String()
+	want := "\rprompt> he\r\nllo world\x1b[K\x1b[11D"
+	if got != want {
+		t.Fatalf("Write corrupted a CSI sequence; got %q, want %q", got, want)
+	}
+}